@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// responseCache is a small TTL cache for expensive check results, so a
+// scrape storm against /health or /metrics doesn't re-run every probe on
+// every hit. The interface is deliberately tiny so a memcached/redis-backed
+// implementation can be dropped in later without touching callers.
+type responseCache interface {
+	Set(key string, v CheckResult) error
+	Get(key string) (CheckResult, bool)
+}
+
+type cacheEntry struct {
+	value   CheckResult
+	expires time.Time
+}
+
+// inMemoryCache is the default responseCache backend.
+type inMemoryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func newInMemoryCache(ttl time.Duration) *inMemoryCache {
+	return &inMemoryCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *inMemoryCache) Set(key string, v CheckResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{value: v, expires: time.Now().Add(c.ttl)}
+	return nil
+}
+
+func (c *inMemoryCache) Get(key string) (CheckResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return CheckResult{}, false
+	}
+	return entry.value, true
+}
+
+// uncacheableErrors lists substrings of a failing CheckResult.Message that
+// must never be cached: pinning a transient timeout or resource-exhaustion
+// error would keep reporting it long after the underlying probe recovered.
+var uncacheableErrors = []string{
+	"context deadline exceeded",
+	"cannot allocate memory",
+	"connection refused",
+}
+
+func isCacheable(r CheckResult) bool {
+	if r.Status != StatusFail {
+		return true
+	}
+	for _, needle := range uncacheableErrors {
+		if strings.Contains(r.Message, needle) {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedChecker wraps another Checker so repeated Check calls within the
+// cache's TTL reuse the last result, except for results matching
+// uncacheableErrors, which always bypass the cache.
+type cachedChecker struct {
+	name  string
+	inner Checker
+	cache responseCache
+}
+
+func newCachedChecker(inner Checker, cache responseCache) *cachedChecker {
+	return &cachedChecker{name: inner.Name(), inner: inner, cache: cache}
+}
+
+func (c *cachedChecker) Name() string { return c.name }
+
+func (c *cachedChecker) Check(ctx context.Context) CheckResult {
+	if cached, ok := c.cache.Get(c.name); ok {
+		return cached
+	}
+
+	result := c.inner.Check(ctx)
+	if isCacheable(result) {
+		c.cache.Set(c.name, result)
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubChecker struct {
+	name    string
+	results []CheckResult
+	calls   int // number of times Check has been invoked
+	nextIdx int // index into results returned by the next Check call
+}
+
+func (s *stubChecker) Name() string { return s.name }
+
+func (s *stubChecker) Check(ctx context.Context) CheckResult {
+	s.calls++
+	r := s.results[s.nextIdx]
+	if s.nextIdx < len(s.results)-1 {
+		s.nextIdx++
+	}
+	return r
+}
+
+func TestCachedCheckerReusesCacheableResult(t *testing.T) {
+	inner := &stubChecker{
+		name: "system_memory",
+		results: []CheckResult{
+			{Status: StatusOK, Message: "10.0%"},
+			{Status: StatusWarn, Message: "90.0%"},
+		},
+	}
+	cache := newInMemoryCache(time.Minute)
+	checker := newCachedChecker(inner, cache)
+
+	first := checker.Check(context.Background())
+	second := checker.Check(context.Background())
+
+	if first != second {
+		t.Fatalf("expected cached result to be reused, got %+v then %+v", first, second)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected inner checker to be called once, got %d calls", inner.calls)
+	}
+}
+
+func TestCachedCheckerNeverCachesTimeouts(t *testing.T) {
+	for _, msg := range []string{
+		"context deadline exceeded",
+		"dial tcp: cannot allocate memory",
+		"dial tcp 127.0.0.1:5432: connection refused",
+	} {
+		inner := &stubChecker{
+			name:    "database",
+			results: []CheckResult{{Status: StatusFail, Message: msg}, {Status: StatusOK, Message: "recovered"}},
+		}
+		cache := newInMemoryCache(time.Minute)
+		checker := newCachedChecker(inner, cache)
+
+		first := checker.Check(context.Background())
+		if first.Status != StatusFail {
+			t.Fatalf("expected first call to surface the failure, got %+v", first)
+		}
+
+		if _, ok := cache.Get("database"); ok {
+			t.Fatalf("expected %q not to be cached", msg)
+		}
+
+		second := checker.Check(context.Background())
+		if second.Status != StatusOK {
+			t.Fatalf("expected second call to re-run the probe and recover, got %+v", second)
+		}
+	}
+}
+
+func TestIsCacheable(t *testing.T) {
+	cases := []struct {
+		result CheckResult
+		want   bool
+	}{
+		{CheckResult{Status: StatusOK}, true},
+		{CheckResult{Status: StatusWarn, Message: "90%"}, true},
+		{CheckResult{Status: StatusFail, Message: "disk unreadable"}, true},
+		{CheckResult{Status: StatusFail, Message: "context deadline exceeded"}, false},
+		{CheckResult{Status: StatusFail, Message: errors.New("connection refused").Error()}, false},
+	}
+
+	for _, c := range cases {
+		if got := isCacheable(c.result); got != c.want {
+			t.Errorf("isCacheable(%+v) = %v, want %v", c.result, got, c.want)
+		}
+	}
+}
+
+func TestInMemoryCacheExpiresAfterTTL(t *testing.T) {
+	cache := newInMemoryCache(10 * time.Millisecond)
+	cache.Set("k", CheckResult{Status: StatusOK})
+
+	if _, ok := cache.Get("k"); !ok {
+		t.Fatal("expected value to be present immediately after Set")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := cache.Get("k"); ok {
+		t.Fatal("expected value to be expired after TTL")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log"
+
+	"github.com/google/gops/agent"
+)
+
+// startGopsAgent starts the embedded gops diagnostics agent when opted in via
+// GOPS_ENABLED=1. It is never started in prod, since it listens on a local
+// TCP port that `gops` (memstats/stack/pprof-heap/tree) attaches to, and that
+// surface shouldn't be exposed in a production environment. The returned
+// stop function is a no-op if the agent was never started, so callers can
+// defer it unconditionally.
+func startGopsAgent(environment string) (stop func()) {
+	if getEnv("GOPS_ENABLED", "0") != "1" {
+		return func() {}
+	}
+	if environment == "prod" {
+		log.Println("🚫 GOPS_ENABLED ignored in prod for security")
+		return func() {}
+	}
+
+	// A fixed default (rather than ":0") so the logged address is the one
+	// operators actually attach `gops` to, not a randomly chosen port.
+	addr := getEnv("GOPS_ADDR", "127.0.0.1:8848")
+	if err := agent.Listen(agent.Options{Addr: addr}); err != nil {
+		log.Printf("⚠️  failed to start gops agent: %v", err)
+		return func() {}
+	}
+
+	log.Printf("🩺 gops agent listening on %s, attach with: gops stack|memstats|pprof-heap|tree <addr>", addr)
+	return agent.Close
+}
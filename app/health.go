@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	// Registers the "postgres" driver used by sqlChecker/HEALTHCHECK_DB_DRIVER
+	// by default. Operators targeting a different database should blank-import
+	// their own driver instead and set HEALTHCHECK_DB_DRIVER accordingly.
+	_ "github.com/lib/pq"
+)
+
+// Severity levels for an individual check result.
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// Default timeout applied to a check when none is configured.
+const defaultCheckTimeout = 5 * time.Second
+
+// CheckResult is what a Checker reports back for a single probe.
+type CheckResult struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// CheckDetail is the shape surfaced under HealthResponse.Details, combining a
+// check's result with whether it is allowed to take the service unhealthy.
+type CheckDetail struct {
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	Critical bool   `json:"critical"`
+}
+
+// Checker is a single health probe. Implementations should respect ctx and
+// return promptly when it is cancelled.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) CheckResult
+}
+
+type registeredCheck struct {
+	checker  Checker
+	critical bool
+	timeout  time.Duration
+}
+
+// Registry fans a set of registered Checkers out in parallel and aggregates
+// their results into the shape healthHandler needs.
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]registeredCheck
+}
+
+// NewRegistry returns an empty Registry ready for RegisterCheck calls.
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]registeredCheck)}
+}
+
+// RegisterCheck adds a named Checker to the registry. critical controls
+// whether a "fail" result from this check marks the service unhealthy (503)
+// rather than merely degraded.
+func (r *Registry) RegisterCheck(name string, critical bool, c Checker) {
+	r.RegisterCheckWithTimeout(name, critical, c, defaultCheckTimeout)
+}
+
+// RegisterCheckWithTimeout is RegisterCheck with an explicit per-check
+// timeout instead of defaultCheckTimeout.
+func (r *Registry) RegisterCheckWithTimeout(name string, critical bool, c Checker, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = registeredCheck{checker: c, critical: critical, timeout: timeout}
+}
+
+// RunAll executes every registered check in parallel, each bounded by its own
+// timeout, and returns the per-check details plus the overall status/HTTP
+// code healthHandler should report.
+func (r *Registry) RunAll(ctx context.Context) (map[string]CheckDetail, string, int) {
+	r.mu.RLock()
+	snapshot := make(map[string]registeredCheck, len(r.checks))
+	for name, rc := range r.checks {
+		snapshot[name] = rc
+	}
+	r.mu.RUnlock()
+
+	details := make(map[string]CheckDetail, len(snapshot))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, rc := range snapshot {
+		wg.Add(1)
+		go func(name string, rc registeredCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, rc.timeout)
+			defer cancel()
+
+			result := rc.checker.Check(checkCtx)
+			if checkCtx.Err() != nil && result.Status == "" {
+				result = CheckResult{Status: StatusFail, Message: "check timed out"}
+			}
+
+			mu.Lock()
+			details[name] = CheckDetail{Status: result.Status, Message: result.Message, Critical: rc.critical}
+			mu.Unlock()
+		}(name, rc)
+	}
+
+	wg.Wait()
+
+	// Only a critical check failing is allowed to flip the HTTP status to
+	// 503; a non-critical warn/fail merely degrades the reported status so
+	// liveness/readiness probes don't restart or evict the pod over it.
+	status := "healthy"
+	httpStatus := http.StatusOK
+	for _, d := range details {
+		switch d.Status {
+		case StatusFail:
+			if d.Critical {
+				status = "unhealthy"
+				httpStatus = http.StatusServiceUnavailable
+			} else if status != "unhealthy" {
+				status = "degraded"
+			}
+		case StatusWarn:
+			if status != "unhealthy" {
+				status = "degraded"
+			}
+		}
+	}
+
+	return details, status, httpStatus
+}
+
+// goMemoryChecker flags the existing "Go memory usage is concerning" case.
+type goMemoryChecker struct {
+	warnMB uint64
+}
+
+func (c *goMemoryChecker) Name() string { return "go_memory" }
+
+func (c *goMemoryChecker) Check(ctx context.Context) CheckResult {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	sysMB := m.Sys / 1024 / 1024
+	if sysMB > c.warnMB {
+		return CheckResult{Status: StatusWarn, Message: fmt.Sprintf("%d MB", sysMB)}
+	}
+	return CheckResult{Status: StatusOK, Message: fmt.Sprintf("%d MB", sysMB)}
+}
+
+// systemMemoryChecker flags the existing "system memory usage > 80%" case.
+type systemMemoryChecker struct {
+	warnPercent float64
+}
+
+func (c *systemMemoryChecker) Name() string { return "system_memory" }
+
+func (c *systemMemoryChecker) Check(ctx context.Context) CheckResult {
+	mem, err := getSystemMemory()
+	if err != nil {
+		return CheckResult{Status: StatusWarn, Message: err.Error()}
+	}
+	if mem.usagePercent > c.warnPercent {
+		return CheckResult{Status: StatusWarn, Message: fmt.Sprintf("%.1f%%", mem.usagePercent)}
+	}
+	return CheckResult{Status: StatusOK, Message: fmt.Sprintf("%.1f%%", mem.usagePercent)}
+}
+
+// sqlChecker pings a database and flags connection pool exhaustion, the
+// pattern behind many production SQL outages: the pool looks "up" right up
+// until every connection is checked out.
+type sqlChecker struct {
+	db          *sql.DB
+	maxInUse    int
+	minFreeConn int
+}
+
+func (c *sqlChecker) Name() string { return "database" }
+
+func (c *sqlChecker) Check(ctx context.Context) CheckResult {
+	if err := c.db.PingContext(ctx); err != nil {
+		return CheckResult{Status: StatusFail, Message: err.Error()}
+	}
+
+	stats := c.db.Stats()
+	freeConn := stats.Idle
+	inUse := stats.InUse
+
+	if c.maxInUse > 0 && inUse >= c.maxInUse {
+		return CheckResult{Status: StatusWarn, Message: fmt.Sprintf("pool near exhaustion: inUse=%d", inUse)}
+	}
+	if c.minFreeConn > 0 && freeConn < c.minFreeConn {
+		return CheckResult{Status: StatusWarn, Message: fmt.Sprintf("low free connections: free=%d", freeConn)}
+	}
+
+	return CheckResult{Status: StatusOK, Message: fmt.Sprintf("inUse=%d free=%d", inUse, freeConn)}
+}
+
+// httpUpstreamChecker verifies a downstream HTTP dependency is reachable.
+type httpUpstreamChecker struct {
+	url    string
+	client *http.Client
+}
+
+func (c *httpUpstreamChecker) Name() string { return "http_upstream" }
+
+func (c *httpUpstreamChecker) Check(ctx context.Context) CheckResult {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return CheckResult{Status: StatusFail, Message: err.Error()}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return CheckResult{Status: StatusFail, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return CheckResult{Status: StatusFail, Message: fmt.Sprintf("status %d", resp.StatusCode)}
+	}
+	if resp.StatusCode >= 400 {
+		return CheckResult{Status: StatusWarn, Message: fmt.Sprintf("status %d", resp.StatusCode)}
+	}
+
+	return CheckResult{Status: StatusOK, Message: fmt.Sprintf("status %d", resp.StatusCode)}
+}
+
+// diskSpaceChecker flags low free space on a mounted path.
+type diskSpaceChecker struct {
+	path      string
+	minFreeMB uint64
+}
+
+func (c *diskSpaceChecker) Name() string { return "disk_space" }
+
+func (c *diskSpaceChecker) Check(ctx context.Context) CheckResult {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(c.path, &stat); err != nil {
+		return CheckResult{Status: StatusFail, Message: err.Error()}
+	}
+
+	freeMB := (stat.Bavail * uint64(stat.Bsize)) / 1024 / 1024
+	if freeMB < c.minFreeMB {
+		return CheckResult{Status: StatusWarn, Message: fmt.Sprintf("%d MB free", freeMB)}
+	}
+	return CheckResult{Status: StatusOK, Message: fmt.Sprintf("%d MB free", freeMB)}
+}
+
+// defaultHTTPClient is shared by checkers that make outbound calls, with a
+// short dial timeout so a slow upstream can't stall the whole fan-out.
+var defaultHTTPClient = &http.Client{
+	Timeout: defaultCheckTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: 2 * time.Second}).DialContext,
+	},
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -23,15 +27,17 @@ type Response struct {
 
 // HealthResponse for health checks
 type HealthResponse struct {
-	Status    string            `json:"status"`
-	Details   map[string]string `json:"details,omitempty"`
-	Timestamp time.Time         `json:"timestamp"`
+	Status    string                 `json:"status"`
+	Details   map[string]CheckDetail `json:"details,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
 }
 
 // Global app state
 var (
 	appInitialized = false
 	startTime      = time.Now()
+	checkRegistry  = NewRegistry()
+	memLimiter     = newMemoryLimiter()
 )
 
 // System memory information
@@ -110,25 +116,64 @@ func main() {
 		log.Println("✅ Application initialized and ready")
 	}()
 
+	registerBuiltinChecks(checkRegistry)
+
+	monitorCtx, cancelMonitor := context.WithCancel(context.Background())
+	if memLimiter.enabled() {
+		go memLimiter.monitor(monitorCtx)
+		checkRegistry.RegisterCheck("memory_cap", false, &memoryCapChecker{limiter: memLimiter})
+	}
+
+	stopGops := startGopsAgent(environment)
+
 	// Setup routes
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/", instrumented("/", func(w http.ResponseWriter, r *http.Request) {
 		homeHandler(w, r, environment, version)
-	})
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/ready", readyHandler)
-	
+	}))
+	http.HandleFunc("/health", instrumented("/health", healthHandler))
+	http.HandleFunc("/ready", instrumented("/ready", readyHandler))
+	http.HandleFunc("/metrics", instrumented("/metrics", metricsHandler))
+
 	// Debug endpoint only available in non-production environments
 	if environment != "prod" {
-		http.HandleFunc("/debug/memory", debugMemoryHandler)
+		http.HandleFunc("/debug/memory", instrumented("/debug/memory", debugMemoryHandler))
 		log.Printf("🔧 Debug endpoints enabled for non-production environment: '%s'", environment)
 	} else {
 		log.Printf("🚫 Debug endpoints disabled in production for security: '%s'", environment)
 	}
 
+	server := &http.Server{Addr: ":" + port}
+
 	// Start server
-	log.Printf("🚀 Server starting on port %s in %s environment (version: %s)\n", port, environment, version)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatal("Server failed to start:", err)
+	go func() {
+		log.Printf("🚀 Server starting on port %s in %s environment (version: %s)\n", port, environment, version)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Server failed to start:", err)
+		}
+	}()
+
+	// Block until SIGTERM/SIGINT, then drain in-flight requests.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	<-sigCh
+
+	log.Println("🛑 Shutdown signal received, draining in-flight requests")
+	stopGops()
+	cancelMonitor()
+
+	shutdownTimeout := 10 * time.Second
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			shutdownTimeout = parsed
+		} else {
+			log.Printf("⚠️  invalid SHUTDOWN_TIMEOUT=%q, using default %s", v, shutdownTimeout)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  graceful shutdown failed: %v", err)
 	}
 }
 
@@ -170,38 +215,11 @@ func homeHandler(w http.ResponseWriter, r *http.Request, environment, version st
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
-	// Production-optimized health checks for GKE
-	checks := make(map[string]string)
-
-	// Only critical Go runtime check (memory leak detection)
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-
-	// Alert only if Go memory usage is concerning (> 100MB)
-	if m.Sys/1024/1024 > 100 {
-		checks["go_memory"] = fmt.Sprintf("WARNING: %d MB", m.Sys/1024/1024)
-	}
-
-	// Critical system memory check (only if > 80%)
-	if systemMem, err := getSystemMemory(); err == nil {
-		if systemMem.usagePercent > 80 {
-			checks["system_memory"] = fmt.Sprintf("WARNING: %.1f%%", systemMem.usagePercent)
-		}
-	}
-
-	// Determine overall health
-	status := "healthy"
-	httpStatus := http.StatusOK
-
-	// Only unhealthy if there are warnings
-	if len(checks) > 0 {
-		status = "degraded"
-		httpStatus = http.StatusServiceUnavailable
-	}
+	details, status, httpStatus := checkRegistry.RunAll(r.Context())
 
 	response := HealthResponse{
 		Status:    status,
-		Details:   checks,
+		Details:   details,
 		Timestamp: time.Now(),
 	}
 
@@ -214,12 +232,91 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// registerBuiltinChecks wires up the checks this service always ships with,
+// plus optional dependency probes enabled via environment variables so
+// operators can register their own without touching this file.
+func registerBuiltinChecks(reg *Registry) {
+	cacheTTL := 5 * time.Second
+	if v := os.Getenv("HEALTHCHECK_CACHE_TTL"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			cacheTTL = parsed
+		} else {
+			log.Printf("⚠️  invalid HEALTHCHECK_CACHE_TTL=%q, using default %s", v, cacheTTL)
+		}
+	}
+	cache := newInMemoryCache(cacheTTL)
+
+	reg.RegisterCheck("go_memory", false, &goMemoryChecker{warnMB: 100})
+	reg.RegisterCheck("system_memory", false, newCachedChecker(&systemMemoryChecker{warnPercent: 80}, cache))
+
+	if dsn := os.Getenv("HEALTHCHECK_DB_DSN"); dsn != "" {
+		driver := getEnv("HEALTHCHECK_DB_DRIVER", "postgres")
+		if db, err := sql.Open(driver, dsn); err != nil {
+			log.Printf("⚠️  could not register database health check: %v", err)
+		} else {
+			maxInUse := 0
+			if v := os.Getenv("HEALTHCHECK_DB_MAX_INUSE"); v != "" {
+				if parsed, err := strconv.Atoi(v); err == nil {
+					maxInUse = parsed
+				} else {
+					log.Printf("⚠️  invalid HEALTHCHECK_DB_MAX_INUSE=%q, ignoring", v)
+				}
+			}
+
+			minFreeConn := 0
+			if v := os.Getenv("HEALTHCHECK_DB_MIN_FREE_CONN"); v != "" {
+				if parsed, err := strconv.Atoi(v); err == nil {
+					minFreeConn = parsed
+				} else {
+					log.Printf("⚠️  invalid HEALTHCHECK_DB_MIN_FREE_CONN=%q, ignoring", v)
+				}
+			}
+
+			reg.RegisterCheck("database", true, newCachedChecker(&sqlChecker{db: db, maxInUse: maxInUse, minFreeConn: minFreeConn}, cache))
+		}
+	}
+
+	if url := os.Getenv("HEALTHCHECK_HTTP_URL"); url != "" {
+		reg.RegisterCheck("http_upstream", false, newCachedChecker(&httpUpstreamChecker{url: url, client: defaultHTTPClient}, cache))
+	}
+
+	if path := os.Getenv("HEALTHCHECK_DISK_PATH"); path != "" {
+		minFreeMB := uint64(500)
+		if v := os.Getenv("HEALTHCHECK_DISK_MIN_FREE_MB"); v != "" {
+			if parsed, err := strconv.ParseUint(v, 10, 64); err == nil {
+				minFreeMB = parsed
+			} else {
+				log.Printf("⚠️  invalid HEALTHCHECK_DISK_MIN_FREE_MB=%q, ignoring", v)
+			}
+		}
+		reg.RegisterCheck("disk_space", false, newCachedChecker(&diskSpaceChecker{path: path, minFreeMB: minFreeMB}, cache))
+	}
+}
+
 func readyHandler(w http.ResponseWriter, r *http.Request) {
 	// Simple readiness check for GKE
 	if !appInitialized {
 		response := HealthResponse{
 			Status:    "not ready",
-			Details:   map[string]string{"reason": "initializing"},
+			Details:   map[string]CheckDetail{"reason": {Status: StatusFail, Message: "initializing"}},
+			Timestamp: time.Now(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable) // 503
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Error encoding response: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Past the hard memory cap we want GKE to evict this pod rather than let
+	// it get OOM-killed mid-request.
+	if memLimiter.HardTripped() {
+		response := HealthResponse{
+			Status:    "not ready",
+			Details:   map[string]CheckDetail{"memory_cap": {Status: StatusFail, Message: "hard memory cap exceeded", Critical: true}},
 			Timestamp: time.Now(),
 		}
 
@@ -235,7 +332,7 @@ func readyHandler(w http.ResponseWriter, r *http.Request) {
 	// App is ready - minimal response
 	response := HealthResponse{
 		Status:    "ready",
-		Details:   map[string]string{},
+		Details:   map[string]CheckDetail{},
 		Timestamp: time.Now(),
 	}
 
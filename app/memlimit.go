@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// memoryMonitorInterval is how often the background monitor samples
+// runtime.ReadMemStats while a soft or hard cap is configured.
+const memoryMonitorInterval = 5 * time.Second
+
+// memoryLimiter enforces MEMORY_SOFT_MB/MEMORY_HARD_MB. The hard cap is
+// enforced both by the Go runtime (via debug.SetMemoryLimit, so the GC works
+// to stay under it) and by the monitor goroutine below, which trips /ready
+// into 503 so GKE evicts the pod instead of letting it OOM-kill.
+type memoryLimiter struct {
+	softBytes   uint64
+	hardBytes   uint64
+	softTripped int32
+	hardTripped int32
+}
+
+// newMemoryLimiter reads MEMORY_SOFT_MB/MEMORY_HARD_MB and installs the hard
+// cap with debug.SetMemoryLimit. A zero value for either disables that cap.
+func newMemoryLimiter() *memoryLimiter {
+	softMB := parseMemoryEnvMB("MEMORY_SOFT_MB")
+	hardMB := parseMemoryEnvMB("MEMORY_HARD_MB")
+
+	m := &memoryLimiter{softBytes: softMB * 1024 * 1024, hardBytes: hardMB * 1024 * 1024}
+
+	if hardMB > 0 {
+		debug.SetMemoryLimit(int64(hardMB) * 1024 * 1024)
+		log.Printf("🧯 hard memory limit set to %d MB", hardMB)
+	}
+
+	return m
+}
+
+func parseMemoryEnvMB(key string) uint64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	parsed, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		log.Printf("⚠️  invalid %s=%q, ignoring", key, v)
+		return 0
+	}
+	return parsed
+}
+
+// enabled reports whether either cap is configured, so main can skip
+// starting the monitor goroutine entirely when neither is set.
+func (m *memoryLimiter) enabled() bool {
+	return m.softBytes > 0 || m.hardBytes > 0
+}
+
+// monitor samples memory on a ticker until ctx is cancelled, flipping
+// softTripped/hardTripped as thresholds are crossed in either direction.
+func (m *memoryLimiter) monitor(ctx context.Context) {
+	if !m.enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(memoryMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+
+			soft := m.softBytes > 0 && ms.Sys >= m.softBytes
+			hard := m.hardBytes > 0 && ms.Sys >= m.hardBytes
+
+			setTripped(&m.softTripped, soft)
+			setTripped(&m.hardTripped, hard)
+
+			switch {
+			case hard:
+				log.Printf("🔥 memory hard cap exceeded: %d MB >= %d MB", ms.Sys/1024/1024, m.hardBytes/1024/1024)
+			case soft:
+				log.Printf("⚠️  memory soft cap exceeded: %d MB >= %d MB", ms.Sys/1024/1024, m.softBytes/1024/1024)
+			}
+		}
+	}
+}
+
+func setTripped(addr *int32, v bool) {
+	if v {
+		atomic.StoreInt32(addr, 1)
+	} else {
+		atomic.StoreInt32(addr, 0)
+	}
+}
+
+func (m *memoryLimiter) SoftTripped() bool { return atomic.LoadInt32(&m.softTripped) == 1 }
+func (m *memoryLimiter) HardTripped() bool { return atomic.LoadInt32(&m.hardTripped) == 1 }
+
+// memoryCapChecker surfaces the limiter's state as a Checker so /health
+// degrades at the soft cap the same way any other check would.
+type memoryCapChecker struct {
+	limiter *memoryLimiter
+}
+
+func (c *memoryCapChecker) Name() string { return "memory_cap" }
+
+func (c *memoryCapChecker) Check(ctx context.Context) CheckResult {
+	if c.limiter.HardTripped() {
+		return CheckResult{Status: StatusFail, Message: "hard memory cap exceeded"}
+	}
+	if c.limiter.SoftTripped() {
+		return CheckResult{Status: StatusWarn, Message: "soft memory cap exceeded"}
+	}
+	return CheckResult{Status: StatusOK}
+}
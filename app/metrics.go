@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// labels is a small label set for a single metric sample, e.g.
+// {"path": "/health", "code": "200"}.
+type labels map[string]string
+
+// key renders labels in a stable, comparable form so equal label sets collide
+// in the underlying map regardless of insertion order.
+func (l labels) key() string {
+	if len(l) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(l))
+	for k := range l {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(l[name])
+	}
+	return b.String()
+}
+
+// format renders labels as Prometheus text-format label pairs, e.g. `{a="1",b="2"}`.
+func (l labels) format() string {
+	if len(l) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(l))
+	for k := range l {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%q", name, l[name])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// gaugeVec is a set of gauges distinguished by label set.
+type gaugeVec struct {
+	mu     sync.Mutex
+	values map[string]float64
+	labels map[string]labels
+}
+
+func newGaugeVec() *gaugeVec {
+	return &gaugeVec{values: make(map[string]float64), labels: make(map[string]labels)}
+}
+
+func (g *gaugeVec) Set(l labels, v float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	k := l.key()
+	g.values[k] = v
+	g.labels[k] = l
+}
+
+// counterVec is a set of monotonically increasing counters distinguished by
+// label set.
+type counterVec struct {
+	mu     sync.Mutex
+	values map[string]float64
+	labels map[string]labels
+}
+
+func newCounterVec() *counterVec {
+	return &counterVec{values: make(map[string]float64), labels: make(map[string]labels)}
+}
+
+func (c *counterVec) Inc(l labels) {
+	c.Add(l, 1)
+}
+
+func (c *counterVec) Add(l labels, delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := l.key()
+	c.values[k] += delta
+	c.labels[k] = l
+}
+
+// histogramVec tracks observation counts/sums per fixed bucket boundary, the
+// same shape as a Prometheus histogram, distinguished by label set.
+type histogramVec struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  map[string][]uint64
+	sums    map[string]float64
+	total   map[string]uint64
+	labels  map[string]labels
+}
+
+// defaultLatencyBuckets covers sub-millisecond to multi-second handlers.
+var defaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+func newHistogramVec(buckets []float64) *histogramVec {
+	return &histogramVec{
+		buckets: buckets,
+		counts:  make(map[string][]uint64),
+		sums:    make(map[string]float64),
+		total:   make(map[string]uint64),
+		labels:  make(map[string]labels),
+	}
+}
+
+func (h *histogramVec) Observe(l labels, v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := l.key()
+	if _, ok := h.counts[k]; !ok {
+		h.counts[k] = make([]uint64, len(h.buckets))
+		h.labels[k] = l
+	}
+	for i, le := range h.buckets {
+		if v <= le {
+			h.counts[k][i]++
+		}
+	}
+	h.sums[k] += v
+	h.total[k]++
+}
+
+// metricsRegistry holds every gauge/counter/histogram this service exposes on
+// /metrics, fed by the same probes that answer /health.
+type metricsRegistry struct {
+	httpRequestsTotal   *counterVec
+	httpRequestDuration *histogramVec
+	healthCheckStatus   *gaugeVec
+	healthCheckDuration *gaugeVec
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		httpRequestsTotal:   newCounterVec(),
+		httpRequestDuration: newHistogramVec(defaultLatencyBuckets),
+		healthCheckStatus:   newGaugeVec(),
+		healthCheckDuration: newGaugeVec(),
+	}
+}
+
+var metrics = newMetricsRegistry()
+
+// statusRecorder wraps a ResponseWriter so middleware can observe the status
+// code a handler actually wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// instrumented wraps next so every request records http_requests_total and
+// http_request_duration_seconds under the given path label.
+func instrumented(path string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next(rec, r)
+		duration := time.Since(start).Seconds()
+
+		metrics.httpRequestDuration.Observe(labels{"path": path}, duration)
+		metrics.httpRequestsTotal.Inc(labels{"path": path, "code": fmt.Sprintf("%d", rec.status)})
+	}
+}
+
+// metricsHandler runs the health registry's checks itself (so /metrics stays
+// accurate even under scrape-only traffic) and renders everything as
+// Prometheus text format.
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	details, _, _ := checkRegistry.RunAll(r.Context())
+	checkDuration := time.Since(start).Seconds()
+
+	for name, d := range details {
+		status := 0.0
+		if d.Status == StatusOK {
+			status = 1
+		}
+		metrics.healthCheckStatus.Set(labels{"name": name}, status)
+		metrics.healthCheckDuration.Set(labels{"name": name}, checkDuration)
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	readyValue := 0.0
+	if appInitialized {
+		readyValue = 1
+	}
+
+	var b strings.Builder
+
+	writeGauge(&b, "health_check_status", "Health of an individual registered check (1=ok, 0=not ok)", metrics.healthCheckStatus)
+	writeGauge(&b, "health_check_duration_seconds", "How long the health registry took to run its last check pass", metrics.healthCheckDuration)
+
+	fmt.Fprintf(&b, "# HELP go_memstats_sys_bytes Bytes of memory obtained from the OS by the Go runtime.\n")
+	fmt.Fprintf(&b, "# TYPE go_memstats_sys_bytes gauge\n")
+	fmt.Fprintf(&b, "go_memstats_sys_bytes %d\n", m.Sys)
+
+	fmt.Fprintf(&b, "# HELP system_memory_usage_percent Percentage of system memory in use.\n")
+	fmt.Fprintf(&b, "# TYPE system_memory_usage_percent gauge\n")
+	if sysMem, err := getSystemMemory(); err == nil {
+		fmt.Fprintf(&b, "system_memory_usage_percent %.2f\n", sysMem.usagePercent)
+	}
+
+	fmt.Fprintf(&b, "# HELP app_ready Whether the application has finished startup (1=ready).\n")
+	fmt.Fprintf(&b, "# TYPE app_ready gauge\n")
+	fmt.Fprintf(&b, "app_ready %.0f\n", readyValue)
+
+	writeCounter(&b, "http_requests_total", "Total HTTP requests by path and status code.", metrics.httpRequestsTotal)
+	writeHistogram(&b, "http_request_duration_seconds", "HTTP request latency by path.", metrics.httpRequestDuration)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+func writeGauge(b *strings.Builder, name, help string, g *gaugeVec) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.values) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+	for k, v := range g.values {
+		fmt.Fprintf(b, "%s%s %v\n", name, g.labels[k].format(), v)
+	}
+}
+
+func writeCounter(b *strings.Builder, name, help string, c *counterVec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.values) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	for k, v := range c.values {
+		fmt.Fprintf(b, "%s%s %v\n", name, c.labels[k].format(), v)
+	}
+}
+
+func writeHistogram(b *strings.Builder, name, help string, h *histogramVec) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.counts) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", name)
+	for k, counts := range h.counts {
+		base := h.labels[k]
+		for i, le := range h.buckets {
+			withLE := labels{"le": fmt.Sprintf("%g", le)}
+			for lk, lv := range base {
+				withLE[lk] = lv
+			}
+			fmt.Fprintf(b, "%s_bucket%s %d\n", name, withLE.format(), counts[i])
+		}
+		withLE := labels{"le": "+Inf"}
+		for lk, lv := range base {
+			withLE[lk] = lv
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", name, withLE.format(), h.total[k])
+		fmt.Fprintf(b, "%s_sum%s %v\n", name, base.format(), h.sums[k])
+		fmt.Fprintf(b, "%s_count%s %d\n", name, base.format(), h.total[k])
+	}
+}